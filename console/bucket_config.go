@@ -0,0 +1,423 @@
+// Copyright 2018 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package console
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"fmt"
+	"github.com/chubaofs/chubaofs/util/log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ObjectVersion mirrors the shape of Object but for a single version of a
+// key returned by ListObjectVersions, so the console UI can browse and
+// restore prior versions.
+type ObjectVersion struct {
+	Size         int64      `json:"size"`
+	OwnerId      string     `json:"ownerId"`
+	OwnerName    string     `json:"ownerName"`
+	ObjectName   string     `json:"objectName"`
+	StorageClass string     `json:"storageClass"`
+	LastModified *time.Time `json:"lastModified"`
+	VersionId    string     `json:"versionId"`
+	IsLatest     bool       `json:"isLatest"`
+	DeleteMarker bool       `json:"deleteMarker"`
+}
+
+// ObjectVersionList is the versions=true counterpart of ObjectList.
+type ObjectVersionList struct {
+	KeyCount            int64            `json:"keyCount"`
+	IsTruncated         bool             `json:"isTruncated"`
+	NextKeyMarker       string           `json:"nextKeyMarker,omitempty"`
+	NextVersionIdMarker string           `json:"nextVersionIdMarker,omitempty"`
+	Versions            []*ObjectVersion `json:"versions"`
+	Directories         []*string        `json:"directories"`
+}
+
+// getObjectVersionListHandler lists one page of ListObjectVersions for
+// bucketName/prefix and writes the result as an ObjectVersionList. It is
+// invoked by getObjectListHandler when the request carries versions=true.
+// keyMarker/versionIdMarker echo back ObjectVersionList.NextKeyMarker and
+// NextVersionIdMarker from a previous call, the same way getObjectListHandler
+// pages ObjectList with startAfter.
+func (c *Console) getObjectVersionListHandler(w http.ResponseWriter, s3Client *s3.S3, bucketName, prefix, maxKeys, keyMarker, versionIdMarker string) {
+	input := &s3.ListObjectVersionsInput{
+		Bucket:    aws.String(bucketName),
+		Delimiter: aws.String("/"),
+	}
+	if len(prefix) > 0 {
+		input.SetPrefix(prefix)
+	}
+	if len(keyMarker) > 0 {
+		input.SetKeyMarker(keyMarker)
+	}
+	if len(versionIdMarker) > 0 {
+		input.SetVersionIdMarker(versionIdMarker)
+	}
+	maxKeysInt, _ := strconv.ParseInt(S3ListObjectsMaxKeys, 10, 64)
+	if len(maxKeys) > 0 {
+		if parsed, err := strconv.ParseInt(maxKeys, 10, 64); err == nil {
+			maxKeysInt = parsed
+		}
+	}
+	input.SetMaxKeys(maxKeysInt)
+
+	output, err := s3Client.ListObjectVersions(input)
+	if err != nil {
+		log.LogErrorf("getObjectVersionListHandler : list object versions from bucket %s failed cause : %s", bucketName, err)
+		writeErrorResponse(w, "Get object version list failed")
+		return
+	}
+
+	versions := make([]*ObjectVersion, 0, len(output.Versions)+len(output.DeleteMarkers))
+	for _, v := range output.Versions {
+		version := &ObjectVersion{
+			Size:         aws.Int64Value(v.Size),
+			ObjectName:   aws.StringValue(v.Key),
+			StorageClass: aws.StringValue(v.StorageClass),
+			LastModified: v.LastModified,
+			VersionId:    aws.StringValue(v.VersionId),
+			IsLatest:     aws.BoolValue(v.IsLatest),
+		}
+		if v.Owner != nil {
+			version.OwnerId = aws.StringValue(v.Owner.ID)
+			version.OwnerName = aws.StringValue(v.Owner.DisplayName)
+		}
+		versions = append(versions, version)
+	}
+	for _, m := range output.DeleteMarkers {
+		marker := &ObjectVersion{
+			ObjectName:   aws.StringValue(m.Key),
+			LastModified: m.LastModified,
+			VersionId:    aws.StringValue(m.VersionId),
+			IsLatest:     aws.BoolValue(m.IsLatest),
+			DeleteMarker: true,
+		}
+		if m.Owner != nil {
+			marker.OwnerId = aws.StringValue(m.Owner.ID)
+			marker.OwnerName = aws.StringValue(m.Owner.DisplayName)
+		}
+		versions = append(versions, marker)
+	}
+
+	directories := make([]*string, 0)
+	for _, p := range output.CommonPrefixes {
+		directories = append(directories, p.Prefix)
+	}
+
+	writeDataResponse(w, &ObjectVersionList{
+		KeyCount:            int64(len(versions)),
+		IsTruncated:         aws.BoolValue(output.IsTruncated),
+		NextKeyMarker:       aws.StringValue(output.NextKeyMarker),
+		NextVersionIdMarker: aws.StringValue(output.NextVersionIdMarker),
+		Versions:            versions,
+		Directories:         directories,
+	})
+}
+
+func (c *Console) putBucketVersioningHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "put bucket versioning failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "status")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	status := req["status"].(string)
+
+	_, err = s3Client.PutBucketVersioning(&s3.PutBucketVersioningInput{
+		Bucket: aws.String(bucketName),
+		VersioningConfiguration: &s3.VersioningConfiguration{
+			Status: aws.String(status),
+		},
+	})
+	if err != nil {
+		log.LogErrorf("%s(): put bucket versioning on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
+
+func (c *Console) getBucketVersioningHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "get bucket versioning failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	output, err := s3Client.GetBucketVersioning(&s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): get bucket versioning on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeDataResponse(w, output)
+}
+
+// LifecycleRuleInput is the JSON shape accepted by
+// putBucketLifecycleConfigurationHandler, mirroring the fields of the
+// Terraform aws_s3_bucket lifecycle_rule block.
+type LifecycleRuleInput struct {
+	Id                       string `json:"id"`
+	Prefix                   string `json:"prefix"`
+	Enabled                  bool   `json:"enabled"`
+	ExpirationDays           int64  `json:"expirationDays"`
+	NoncurrentVersionExpDays int64  `json:"noncurrentVersionExpirationDays"`
+	TransitionDays           int64  `json:"transitionDays"`
+	TransitionStorageClass   string `json:"transitionStorageClass"`
+}
+
+func (c *Console) putBucketLifecycleConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "put bucket lifecycle configuration failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "rules")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	rawRules, ok := req["rules"].([]interface{})
+	if !ok {
+		log.LogErrorf("%s(): rules parameter is malformed", getCaller())
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	rules := make([]*s3.LifecycleRule, 0, len(rawRules))
+	for _, raw := range rawRules {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		status := s3.ExpirationStatusDisabled
+		if enabled, _ := m["enabled"].(bool); enabled {
+			status = s3.ExpirationStatusEnabled
+		}
+
+		rule := &s3.LifecycleRule{
+			ID:     aws.String(stringField(m, "id")),
+			Prefix: aws.String(stringField(m, "prefix")),
+			Status: aws.String(status),
+		}
+
+		if days, err := strconv.ParseInt(fmt.Sprintf("%v", m["expirationDays"]), 10, 64); err == nil && days > 0 {
+			rule.Expiration = &s3.LifecycleExpiration{Days: aws.Int64(days)}
+		}
+		if days, err := strconv.ParseInt(fmt.Sprintf("%v", m["noncurrentVersionExpirationDays"]), 10, 64); err == nil && days > 0 {
+			rule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{NoncurrentDays: aws.Int64(days)}
+		}
+		if days, err := strconv.ParseInt(fmt.Sprintf("%v", m["transitionDays"]), 10, 64); err == nil && days > 0 {
+			rule.Transitions = []*s3.Transition{{
+				Days:         aws.Int64(days),
+				StorageClass: aws.String(stringField(m, "transitionStorageClass")),
+			}}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	_, err = s3Client.PutBucketLifecycleConfiguration(&s3.PutBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucketName),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{
+			Rules: rules,
+		},
+	})
+	if err != nil {
+		log.LogErrorf("%s(): put bucket lifecycle configuration on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
+
+func (c *Console) getBucketLifecycleConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "get bucket lifecycle configuration failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	output, err := s3Client.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): get bucket lifecycle configuration on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeDataResponse(w, output.Rules)
+}
+
+// CorsRuleInput is the JSON shape accepted by putBucketCorsHandler,
+// mirroring the Terraform aws_s3_bucket cors_rule block.
+type CorsRuleInput struct {
+	AllowedHeaders []string `json:"allowedHeaders"`
+	AllowedMethods []string `json:"allowedMethods"`
+	AllowedOrigins []string `json:"allowedOrigins"`
+	MaxAgeSeconds  int64    `json:"maxAgeSeconds"`
+}
+
+func (c *Console) putBucketCorsHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "put bucket cors failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "corsRules")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	rawRules, ok := req["corsRules"].([]interface{})
+	if !ok {
+		log.LogErrorf("%s(): corsRules parameter is malformed", getCaller())
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	corsRules := make([]*s3.CORSRule, 0, len(rawRules))
+	for _, raw := range rawRules {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		corsRule := &s3.CORSRule{
+			AllowedHeaders: toStringSlice(m["allowedHeaders"]),
+			AllowedMethods: toStringSlice(m["allowedMethods"]),
+			AllowedOrigins: toStringSlice(m["allowedOrigins"]),
+		}
+		if maxAge, err := strconv.ParseInt(fmt.Sprintf("%v", m["maxAgeSeconds"]), 10, 64); err == nil && maxAge > 0 {
+			corsRule.MaxAgeSeconds = aws.Int64(maxAge)
+		}
+		corsRules = append(corsRules, corsRule)
+	}
+
+	_, err = s3Client.PutBucketCors(&s3.PutBucketCorsInput{
+		Bucket: aws.String(bucketName),
+		CORSConfiguration: &s3.CORSConfiguration{
+			CORSRules: corsRules,
+		},
+	})
+	if err != nil {
+		log.LogErrorf("%s(): put bucket cors on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
+
+func (c *Console) getBucketCorsHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "get bucket cors failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	output, err := s3Client.GetBucketCors(&s3.GetBucketCorsInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): get bucket cors on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeDataResponse(w, output.CORSRules)
+}
+
+func (c *Console) deleteBucketCorsHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "delete bucket cors failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	_, err = s3Client.DeleteBucketCors(&s3.DeleteBucketCorsInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): delete bucket cors on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
+
+// stringField returns m[key] as a string, or "" if the key is absent -
+// unlike fmt.Sprintf("%v", m[key]), which would stringify a missing key
+// as the literal "<nil>".
+func stringField(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// toStringSlice converts a decoded JSON []interface{} of strings into a
+// []*string suitable for the AWS SDK's string-pointer-slice fields.
+func toStringSlice(v interface{}) []*string {
+	raw, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]*string, 0, len(raw))
+	for _, item := range raw {
+		out = append(out, aws.String(fmt.Sprintf("%v", item)))
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+// Copyright 2018 The ChubaoFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package console
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/chubaofs/chubaofs/util/log"
+	"net/http"
+)
+
+// defaultSSEAlgorithm is used when a PutBucketEncryption request does not
+// name one explicitly.
+const defaultSSEAlgorithm = s3.ServerSideEncryptionAes256
+
+func (c *Console) putBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "put bucket encryption failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	sseAlgorithm := defaultSSEAlgorithm
+	if v, ok := req["sseAlgorithm"]; ok {
+		sseAlgorithm = v.(string)
+	}
+
+	rule := &s3.ServerSideEncryptionRule{
+		ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+			SSEAlgorithm: aws.String(sseAlgorithm),
+		},
+	}
+	if v, ok := req["kmsMasterKeyId"]; ok {
+		rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID = aws.String(v.(string))
+	}
+
+	_, err = s3Client.PutBucketEncryption(&s3.PutBucketEncryptionInput{
+		Bucket: aws.String(bucketName),
+		ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+			Rules: []*s3.ServerSideEncryptionRule{rule},
+		},
+	})
+	if err != nil {
+		log.LogErrorf("%s(): put bucket encryption on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
+
+func (c *Console) getBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "get bucket encryption failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	output, err := s3Client.GetBucketEncryption(&s3.GetBucketEncryptionInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): get bucket encryption on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeDataResponse(w, output.ServerSideEncryptionConfiguration)
+}
+
+func (c *Console) deleteBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "delete bucket encryption failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	_, err = s3Client.DeleteBucketEncryption(&s3.DeleteBucketEncryptionInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): delete bucket encryption on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
+
+func (c *Console) putBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "put bucket policy failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "policy")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	policy := req["policy"].(string)
+
+	_, err = s3Client.PutBucketPolicy(&s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucketName),
+		Policy: aws.String(policy),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): put bucket policy on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
+
+func (c *Console) getBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "get bucket policy failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	output, err := s3Client.GetBucketPolicy(&s3.GetBucketPolicyInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): get bucket policy on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeDataResponse(w, map[string]string{"policy": aws.StringValue(output.Policy)})
+}
+
+func (c *Console) deleteBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "delete bucket policy failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	_, err = s3Client.DeleteBucketPolicy(&s3.DeleteBucketPolicyInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): delete bucket policy on %s failed cause by [%v]", getCaller(), bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
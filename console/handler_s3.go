@@ -16,6 +16,7 @@ package console
 
 import (
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -30,13 +31,14 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func (c *Console) getS3Keys(w http.ResponseWriter, r *http.Request) (string, string, error) {
 	// parse query parameter
 	params := r.URL.Query()
 	userId, _ := params["userId"]
-	if len(userId[0]) == 0 {
+	if len(userId) == 0 || len(userId[0]) == 0 {
 		log.LogErrorf("getS3Keys : user id is empty")
 		return "", "", errors.New("can not get user id from request")
 	}
@@ -46,6 +48,10 @@ func (c *Console) getS3Keys(w http.ResponseWriter, r *http.Request) (string, str
 		log.LogErrorf("getS3Keys : get access key and secret key from auth node")
 		return "", "", err
 	}
+	if len(keyInfo.AccessKey) == 0 || len(keyInfo.SecretKey) == 0 {
+		log.LogErrorf("getS3Keys : user %s has no S3 capability granted", userId[0])
+		return "", "", &forbiddenError{err: fmt.Errorf("user %s has no S3 capability granted", userId[0])}
+	}
 	return keyInfo.AccessKey, keyInfo.SecretKey, nil
 }
 
@@ -74,7 +80,7 @@ func (c *Console) getBucketListHandler(w http.ResponseWriter, r *http.Request) {
 	s3Client, err := c.getS3Client(w, r)
 	if err != nil {
 		log.LogErrorf("getBucketListHandler : Get s3 client failed cause : %s", err)
-		writeErrorResponse(w, "Get s3 client failed")
+		writePrepareErrorResponse(w, wrapAuthError(err), "Get s3 client failed")
 		return
 	}
 
@@ -110,7 +116,7 @@ func (c *Console) createBucketHandler(w http.ResponseWriter, r *http.Request) {
 	s3Client, err := c.getS3Client(w, r)
 	if err != nil {
 		log.LogErrorf("createBucketHandler : get s3 client failed while create bucket %s cause : %s", bucketName, err)
-		writeErrorResponse(w, "Get s3 client failed")
+		writePrepareErrorResponse(w, wrapAuthError(err), "Get s3 client failed")
 		return
 	}
 
@@ -143,7 +149,7 @@ func (c *Console) deleteBucketHandler(w http.ResponseWriter, r *http.Request) {
 	s3Client, err := c.getS3Client(w, r)
 	if err != nil {
 		log.LogErrorf("deleteBucketHandler : get s3 client failed while deleting bucket %s cause : %s", bucketName, err)
-		writeErrorResponse(w, "Get s3 client failed")
+		writePrepareErrorResponse(w, wrapAuthError(err), "Get s3 client failed")
 		return
 	}
 
@@ -183,15 +189,27 @@ func (c *Console) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 	s3Client, err := c.getS3Client(w, r)
 	if err != nil {
 		log.LogErrorf("putObjectHandler : Get s3 client failed while putting object %s cause : %s", objectName, err)
-		writeErrorResponse(w, "Get s3 client failed")
+		writePrepareErrorResponse(w, wrapAuthError(err), "Get s3 client failed")
 		return
 	}
 
-	output, err := s3Client.PutObject(&s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(objectName),
 		Body:   file,
-	})
+	}
+	// SSE-C is customer-managed: the caller must present the same
+	// algorithm/key again on every subsequent get/head of this object,
+	// the same way getObjectHandler/headObjectHandler require it below.
+	// The console never persists the key.
+	sseAlgorithm := r.MultipartForm.Value["sseCustomerAlgorithm"]
+	sseKey := r.MultipartForm.Value["sseCustomerKey"]
+	if len(sseAlgorithm) > 0 && len(sseKey) > 0 {
+		input.SSECustomerAlgorithm = aws.String(sseAlgorithm[0])
+		input.SSECustomerKey = aws.String(sseKey[0])
+	}
+
+	output, err := s3Client.PutObject(input)
 
 	if err != nil {
 		log.LogErrorf("putObjectHandler : put object %s to bucket %s failed cause : %s", objectName, bucketName, err)
@@ -218,40 +236,137 @@ func (c *Console) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 	s3Client, err := c.getS3Client(w, r)
 	if err != nil {
 		log.LogErrorf("getObjectHandler : Get s3 client failed while getting object cause : %s", err)
-		writeErrorResponse(w, "Get s3 client failed")
+		writePrepareErrorResponse(w, wrapAuthError(err), "Get s3 client failed")
 		return
 	}
 
-	// check object is whether existed
-	headOutput, err := s3Client.HeadObject(&s3.HeadObjectInput{
+	// forward the caller's conditional/range headers straight through to
+	// S3 rather than always fetching the whole object
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(objectName),
-	})
+	}
+	if rangeHeader := r.Header.Get("Range"); len(rangeHeader) > 0 {
+		input.Range = aws.String(rangeHeader)
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 {
+		input.IfNoneMatch = aws.String(ifNoneMatch)
+	}
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); len(ifModifiedSince) > 0 {
+		if t, perr := http.ParseTime(ifModifiedSince); perr == nil {
+			input.IfModifiedSince = aws.Time(t)
+		}
+	}
+	// SSE-C objects require the same key on every read; the console never
+	// persists it, so the caller must resend it with each get.
+	if sseAlgorithm, ok := req["sseCustomerAlgorithm"].(string); ok && len(sseAlgorithm) > 0 {
+		if sseKey, ok := req["sseCustomerKey"].(string); ok && len(sseKey) > 0 {
+			input.SSECustomerAlgorithm = aws.String(sseAlgorithm)
+			input.SSECustomerKey = aws.String(sseKey)
+		}
+	}
+
+	getObjectOutput, err := s3Client.GetObject(input)
 	if err != nil {
-		log.LogErrorf("getObjectHandler : check object %s is whether existed failed cause : %s", objectName, err)
+		if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		log.LogErrorf("getObjectHandler : get object %s from bucket %s failed cause : %s", objectName, bucketName, err)
+		writeErrorResponse(w, "Get object failed")
 		return
 	}
-	size := headOutput.ContentLength
+	defer getObjectOutput.Body.Close()
+
+	header := w.Header()
+	header.Set("Content-Type", aws.StringValue(getObjectOutput.ContentType))
+	header.Set("Content-Disposition", "attachment; filename="+objectName)
+	header.Set("Content-Length", strconv.FormatInt(aws.Int64Value(getObjectOutput.ContentLength), 10))
+	if acceptRanges := aws.StringValue(getObjectOutput.AcceptRanges); len(acceptRanges) > 0 {
+		header.Set("Accept-Ranges", acceptRanges)
+	}
+	if etag := aws.StringValue(getObjectOutput.ETag); len(etag) > 0 {
+		header.Set("ETag", etag)
+	}
+	if getObjectOutput.LastModified != nil {
+		header.Set("Last-Modified", getObjectOutput.LastModified.UTC().Format(http.TimeFormat))
+	}
+	contentRange := aws.StringValue(getObjectOutput.ContentRange)
+	if len(contentRange) > 0 {
+		header.Set("Content-Range", contentRange)
+	}
+	if sse := aws.StringValue(getObjectOutput.ServerSideEncryption); len(sse) > 0 {
+		header.Set("x-amz-server-side-encryption", sse)
+	}
+	if kmsKeyId := aws.StringValue(getObjectOutput.SSEKMSKeyId); len(kmsKeyId) > 0 {
+		header.Set("x-amz-server-side-encryption-aws-kms-key-id", kmsKeyId)
+	}
+
+	status := http.StatusOK
+	if len(contentRange) > 0 {
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
+
+	io.Copy(w, getObjectOutput.Body)
+}
 
-	getObjectOutput, err := s3Client.GetObject(&s3.GetObjectInput{
+// ObjectMetadata is the JSON response of headObjectHandler - everything
+// the object detail page needs to render without fetching the body.
+type ObjectMetadata struct {
+	Size                 int64             `json:"size"`
+	ETag                 string            `json:"eTag"`
+	ContentType          string            `json:"contentType"`
+	LastModified         *time.Time        `json:"lastModified"`
+	StorageClass         string            `json:"storageClass"`
+	Metadata             map[string]string `json:"metadata"`
+	ServerSideEncryption string            `json:"serverSideEncryption,omitempty"`
+	SSEKMSKeyId          string            `json:"sseKmsKeyId,omitempty"`
+}
+
+// headObjectHandler returns object metadata as JSON without fetching the
+// body, so the UI can render object detail pages cheaply.
+func (c *Console) headObjectHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "head object failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	objectName := req["objectName"].(string)
+
+	headInput := &s3.HeadObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(objectName),
-	})
-	responseData := getObjectOutput.Body
-	defer responseData.Close()
+	}
+	if sseAlgorithm, ok := req["sseCustomerAlgorithm"].(string); ok && len(sseAlgorithm) > 0 {
+		if sseKey, ok := req["sseCustomerKey"].(string); ok && len(sseKey) > 0 {
+			headInput.SSECustomerAlgorithm = aws.String(sseAlgorithm)
+			headInput.SSECustomerKey = aws.String(sseKey)
+		}
+	}
 
+	output, err := s3Client.HeadObject(headInput)
 	if err != nil {
-		log.LogErrorf("getObjectHandler : get object %s from bucket %s failed cause : %s", objectName, bucketName, err)
-		writeErrorResponse(w, "Get object failed")
+		log.LogErrorf("%s(): head object %s in bucket %s failed cause by [%v]", getCaller(), objectName, bucketName, err)
+		writeErrorResponse(w, failedResponseInfo)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
-	w.Header().Set("Content-Disposition", "attachment; filename="+objectName)
-	w.Header().Set("Content-Length", strconv.FormatInt(*size, 10))
-
-	io.Copy(w, responseData)
+	writeDataResponse(w, &ObjectMetadata{
+		Size:                 aws.Int64Value(output.ContentLength),
+		ETag:                 aws.StringValue(output.ETag),
+		ContentType:          aws.StringValue(output.ContentType),
+		LastModified:         output.LastModified,
+		StorageClass:         aws.StringValue(output.StorageClass),
+		Metadata:             aws.StringValueMap(output.Metadata),
+		ServerSideEncryption: aws.StringValue(output.ServerSideEncryption),
+		SSEKMSKeyId:          aws.StringValue(output.SSEKMSKeyId),
+	})
 }
 
 func (c *Console) deleteObjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -270,7 +385,7 @@ func (c *Console) deleteObjectHandler(w http.ResponseWriter, r *http.Request) {
 	s3Client, err := c.getS3Client(w, r)
 	if err != nil {
 		log.LogErrorf("deleteObjectHandler : Get s3 client failed while deleting object cause : %s", err)
-		writeErrorResponse(w, "Get s3 client failed")
+		writePrepareErrorResponse(w, wrapAuthError(err), "Get s3 client failed")
 		return
 	}
 
@@ -306,12 +421,20 @@ func (c *Console) getObjectListHandler(w http.ResponseWriter, r *http.Request) {
 	s3Client, err := c.getS3Client(w, r)
 	if err != nil {
 		log.LogErrorf("getObjectListHandler : Get s3 client failed while deleting object cause : %s", err)
-		writeErrorResponse(w, "Get s3 client failed")
+		writePrepareErrorResponse(w, wrapAuthError(err), "Get s3 client failed")
+		return
+	}
+
+	if versions, ok := req["versions"].(bool); ok && versions {
+		keyMarker, _ := req["keyMarker"].(string)
+		versionIdMarker, _ := req["versionIdMarker"].(string)
+		c.getObjectVersionListHandler(w, s3Client, bucketName, prefix, maxKeys, keyMarker, versionIdMarker)
 		return
 	}
 
 	input := &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucketName),
+		Bucket:    aws.String(bucketName),
+		Delimiter: aws.String("/"),
 	}
 	if len(prefix) > 0 {
 		input.SetPrefix(prefix)
@@ -363,21 +486,316 @@ func (c *Console) getObjectListHandler(w http.ResponseWriter, r *http.Request) {
 	writeDataResponse(w, objectList)
 }
 
+// PresignedURL wraps a presigned S3 URL returned to the frontend for a
+// direct browser-to-ObjectNode PUT or GET.
+type PresignedURL struct {
+	URL string `json:"url"`
+}
+
+// MultipartUploadInit is returned after a multipart upload is initiated,
+// telling the frontend which upload to address subsequent parts to and
+// what part size to chunk the file into.
+type MultipartUploadInit struct {
+	UploadId string `json:"uploadId"`
+	PartSize int64  `json:"partSize"`
+}
+
+// presignExpireSeconds is the validity window of every presigned
+// upload/download URL handed out to the frontend.
+const presignExpireSeconds = 15 * 60 * time.Second
+
+// s3MultipartPartSize is the default part size used when chunking an
+// upload across multiple UploadPart calls. It can be overridden per
+// request via the "partSize" parameter.
+const s3MultipartPartSize = 8 * 1024 * 1024
+
 func (c *Console) createObjectUrlHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "create object url failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	objectName := req["objectName"].(string)
+
+	presignReq, _ := s3Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(objectName),
+	})
+
+	url, err := presignReq.Presign(presignExpireSeconds)
+	if err != nil {
+		log.LogErrorf("%s(): presign put object url failed cause by [%v]", getCaller(), err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
 
+	writeDataResponse(w, &PresignedURL{URL: url})
 }
 
 func (c *Console) getObjectUrlHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "get object url failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	objectName := req["objectName"].(string)
+
+	presignReq, _ := s3Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(objectName),
+	})
+
+	url, err := presignReq.Presign(presignExpireSeconds)
+	if err != nil {
+		log.LogErrorf("%s(): presign get object url failed cause by [%v]", getCaller(), err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
 
+	writeDataResponse(w, &PresignedURL{URL: url})
+}
+
+func (c *Console) initiateMultipartUploadHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "initiate multipart upload failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	objectName := req["objectName"].(string)
+
+	output, err := s3Client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(objectName),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): create multipart upload failed cause by [%v]", getCaller(), err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	partSize := int64(s3MultipartPartSize)
+	if v, ok := req["partSize"]; ok {
+		if requested, perr := strconv.ParseInt(fmt.Sprintf("%v", v), 10, 64); perr == nil && requested > 0 {
+			partSize = requested
+		}
+	}
+
+	writeDataResponse(w, &MultipartUploadInit{
+		UploadId: aws.StringValue(output.UploadId),
+		PartSize: partSize,
+	})
+}
+
+func (c *Console) uploadPartHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "get upload part url failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName", "uploadId", "partNumber")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	objectName := req["objectName"].(string)
+	uploadId := req["uploadId"].(string)
+
+	partNumber, err := strconv.ParseInt(fmt.Sprintf("%v", req["partNumber"]), 10, 64)
+	if err != nil {
+		log.LogErrorf("%s(): parse part number failed cause by [%v]", getCaller(), err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	presignReq, _ := s3Client.UploadPartRequest(&s3.UploadPartInput{
+		Bucket:     aws.String(bucketName),
+		Key:        aws.String(objectName),
+		UploadId:   aws.String(uploadId),
+		PartNumber: aws.Int64(partNumber),
+	})
+
+	url, err := presignReq.Presign(presignExpireSeconds)
+	if err != nil {
+		log.LogErrorf("%s(): presign upload part url failed cause by [%v]", getCaller(), err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeDataResponse(w, &PresignedURL{URL: url})
+}
+
+func (c *Console) completeMultipartUploadHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "complete multipart upload failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName", "uploadId", "parts")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	objectName := req["objectName"].(string)
+	uploadId := req["uploadId"].(string)
+
+	rawParts, ok := req["parts"].([]interface{})
+	if !ok {
+		log.LogErrorf("%s(): parts parameter is malformed", getCaller())
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	completedParts := make([]*s3.CompletedPart, 0, len(rawParts))
+	for _, p := range rawParts {
+		part, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		partNumber, _ := strconv.ParseInt(fmt.Sprintf("%v", part["partNumber"]), 10, 64)
+		completedParts = append(completedParts, &s3.CompletedPart{
+			PartNumber: aws.Int64(partNumber),
+			ETag:       aws.String(fmt.Sprintf("%v", part["eTag"])),
+		})
+	}
+
+	output, err := s3Client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectName),
+		UploadId: aws.String(uploadId),
+		MultipartUpload: &s3.CompletedMultipartUpload{
+			Parts: completedParts,
+		},
+	})
+	if err != nil {
+		log.LogErrorf("%s(): complete multipart upload failed cause by [%v]", getCaller(), err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	log.LogInfof("Complete multipart upload %s success, and ETag : %s", objectName, aws.StringValue(output.ETag))
+	writeSuccessResponse(w)
+}
+
+func (c *Console) abortMultipartUploadHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "abort multipart upload failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName", "uploadId")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	objectName := req["objectName"].(string)
+	uploadId := req["uploadId"].(string)
+
+	_, err = s3Client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectName),
+		UploadId: aws.String(uploadId),
+	})
+	if err != nil {
+		log.LogErrorf("%s(): abort multipart upload failed cause by [%v]", getCaller(), err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeSuccessResponse(w)
+}
+
+// danglingUploadMaxAgeHours is the default retention window: multipart
+// uploads initiated more than this long ago are assumed abandoned.
+const danglingUploadMaxAgeHours = 24
+
+// abortDanglingMultipartUploadsHandler pages through ListMultipartUploads
+// and aborts every upload older than the retention window, so that parts
+// left behind by crashed browser sessions don't linger in the bucket
+// forever. The window is danglingUploadMaxAgeHours unless the request
+// sets "olderThanHours". It never touches uploads still within that
+// window; an olderThanHours of 0 makes every upload eligible, including
+// ones still in progress.
+func (c *Console) abortDanglingMultipartUploadsHandler(w http.ResponseWriter, r *http.Request) {
+	failedResponseInfo := "cleanup dangling multipart uploads failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+
+	maxAgeHours := float64(danglingUploadMaxAgeHours)
+	if v, ok := req["olderThanHours"]; ok {
+		if parsed, perr := strconv.ParseFloat(fmt.Sprintf("%v", v), 64); perr == nil && parsed >= 0 {
+			maxAgeHours = parsed
+		}
+	}
+	cutoff := time.Now().Add(-time.Duration(maxAgeHours * float64(time.Hour)))
+
+	aborted := make([]string, 0)
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucketName),
+	}
+	for {
+		output, err := s3Client.ListMultipartUploads(input)
+		if err != nil {
+			log.LogErrorf("%s(): list multipart uploads failed cause by [%v]", getCaller(), err)
+			writeErrorResponse(w, failedResponseInfo)
+			return
+		}
+
+		for _, u := range output.Uploads {
+			if u.Initiated == nil || u.Initiated.After(cutoff) {
+				continue
+			}
+
+			_, err = s3Client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(bucketName),
+				Key:      u.Key,
+				UploadId: u.UploadId,
+			})
+			if err != nil {
+				log.LogErrorf("%s(): abort dangling multipart upload %s failed cause by [%v]", getCaller(), aws.StringValue(u.Key), err)
+				continue
+			}
+			aborted = append(aborted, aws.StringValue(u.Key))
+		}
+
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		input.KeyMarker = output.NextKeyMarker
+		input.UploadIdMarker = output.NextUploadIdMarker
+	}
+
+	writeDataResponse(w, aborted)
 }
 
 func (c *Console) createFolderHandler(w http.ResponseWriter, r *http.Request) {
 	failedResponseInfo := "create folder failed!!!"
 
-	s3Client, req, err := prepareHandler(r, "bucketName", "folderName", "parentName")
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "folderName", "parentName")
 	if err != nil {
 		log.LogErrorf("%s(): %s", getCaller(), err)
-		writeErrorResponse(w, failedResponseInfo)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
 		return
 	}
 
@@ -401,29 +819,169 @@ func (c *Console) createFolderHandler(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w)
 }
 
+// DeleteFolderResult reports how many keys under a folder prefix were
+// removed (or would be removed, in dry-run mode) along with any
+// per-key errors returned by DeleteObjects.
+type DeleteFolderResult struct {
+	DeletedCount int64       `json:"deletedCount"`
+	Errors       []*s3.Error `json:"errors,omitempty"`
+}
+
+// s3DeleteObjectsBatchSize is the maximum number of keys DeleteObjects
+// accepts in a single request.
+const s3DeleteObjectsBatchSize = 1000
+
 func (c *Console) listFolderHandler(w http.ResponseWriter, r *http.Request) {
-	//init
+	failedResponseInfo := "list folder failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "folderName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
 
-	//checkfolder
+	bucketName := req["bucketName"].(string)
+	folderName := req["folderName"].(string)
 
-	//do_op
+	objects := make([]*Object, 0)
+	directories := make([]*string, 0)
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:     aws.String(bucketName),
+		Prefix:     aws.String(folderName),
+		Delimiter:  aws.String("/"),
+		FetchOwner: aws.Bool(true),
+	}
+	var pageErr error
+	err = s3Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, o := range page.Contents {
+			if aws.StringValue(o.Key) == folderName {
+				// the folder marker object itself, not a child entry
+				continue
+			}
+			object := &Object{
+				Size:         aws.Int64Value(o.Size),
+				ObjectName:   aws.StringValue(o.Key),
+				StorageClass: aws.StringValue(o.StorageClass),
+				LastModified: o.LastModified,
+			}
+			if o.Owner != nil {
+				object.OwnerId = aws.StringValue(o.Owner.ID)
+				object.OwnerName = aws.StringValue(o.Owner.DisplayName)
+			}
+			objects = append(objects, object)
+		}
+		for _, p := range page.CommonPrefixes {
+			directories = append(directories, p.Prefix)
+		}
+		return true
+	})
+	if err != nil {
+		pageErr = err
+	}
+	if pageErr != nil {
+		log.LogErrorf("%s(): list folder %s failed cause by [%v]", getCaller(), folderName, pageErr)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	writeDataResponse(w, &ObjectList{
+		KeyCount:    int64(len(objects)),
+		Objects:     objects,
+		Directories: directories,
+	})
 }
 
 func (c *Console) deleteFolderHandler(w http.ResponseWriter, r *http.Request) {
-	//init
+	failedResponseInfo := "delete folder failed!!!"
+
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "folderName")
+	if err != nil {
+		log.LogErrorf("%s(): %s", getCaller(), err)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
+		return
+	}
+
+	bucketName := req["bucketName"].(string)
+	folderName := req["folderName"].(string)
+
+	dryRun := false
+	if v, ok := req["dryRun"]; ok {
+		dryRun, _ = v.(bool)
+	}
+
+	// confirmCount is a guard against accidental mass deletions: the
+	// caller must already know (e.g. from a prior listFolderHandler
+	// call) how many keys it expects to remove.
+	var confirmCount int64 = -1
+	if v, ok := req["confirmCount"]; ok {
+		confirmCount, _ = strconv.ParseInt(fmt.Sprintf("%v", v), 10, 64)
+	}
+
+	keys := make([]*s3.ObjectIdentifier, 0)
+	listInput := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+		Prefix: aws.String(folderName),
+	}
+	err = s3Client.ListObjectsV2Pages(listInput, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, o := range page.Contents {
+			keys = append(keys, &s3.ObjectIdentifier{Key: o.Key})
+		}
+		return true
+	})
+	if err != nil {
+		log.LogErrorf("%s(): list folder %s failed cause by [%v]", getCaller(), folderName, err)
+		writeErrorResponse(w, failedResponseInfo)
+		return
+	}
+
+	if confirmCount >= 0 && confirmCount != int64(len(keys)) {
+		log.LogErrorf("%s(): confirm count %d does not match actual key count %d, aborting", getCaller(), confirmCount, len(keys))
+		writeErrorResponse(w, "Confirm count mismatch, delete aborted")
+		return
+	}
 
-	//checkfolder and child object
+	if dryRun {
+		writeDataResponse(w, &DeleteFolderResult{DeletedCount: int64(len(keys))})
+		return
+	}
 
-	//do_op
+	delErrors := make([]*s3.Error, 0)
+	for start := 0; start < len(keys); start += s3DeleteObjectsBatchSize {
+		end := start + s3DeleteObjectsBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		output, err := s3Client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(bucketName),
+			Delete: &s3.Delete{
+				Objects: keys[start:end],
+			},
+		})
+		if err != nil {
+			log.LogErrorf("%s(): delete objects batch failed cause by [%v]", getCaller(), err)
+			writeErrorResponse(w, failedResponseInfo)
+			return
+		}
+		delErrors = append(delErrors, output.Errors...)
+	}
+
+	log.LogInfof("Delete folder %s success, %d keys removed", folderName, len(keys)-len(delErrors))
+	writeDataResponse(w, &DeleteFolderResult{
+		DeletedCount: int64(len(keys)) - int64(len(delErrors)),
+		Errors:       delErrors,
+	})
 }
 
 func (c *Console) getBucketAclHandler(w http.ResponseWriter, r *http.Request) {
 	failedResponseInfo := "get bucket acl failed!!!"
 
-	s3Client, req, err := prepareHandler(r, "bucketName")
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
 	if err != nil {
 		log.LogErrorf("%s(): %s", getCaller(), err)
-		writeErrorResponse(w, failedResponseInfo)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
 		return
 	}
 
@@ -445,10 +1003,10 @@ func (c *Console) getBucketAclHandler(w http.ResponseWriter, r *http.Request) {
 func (c *Console) setBucketAclHandler(w http.ResponseWriter, r *http.Request) {
 	failedResponseInfo := "set bucket acl failed!!!"
 
-	s3Client, req, err := prepareHandler(r, "bucketName")
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName")
 	if err != nil {
 		log.LogErrorf("%s(): %s", getCaller(), err)
-		writeErrorResponse(w, failedResponseInfo)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
 		return
 	}
 
@@ -470,10 +1028,10 @@ func (c *Console) setBucketAclHandler(w http.ResponseWriter, r *http.Request) {
 func (c *Console) getObjectAclHandler(w http.ResponseWriter, r *http.Request) {
 	failedResponseInfo := "get object acl failed!!!"
 
-	s3Client, req, err := prepareHandler(r, "bucketName", "objectName")
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName")
 	if err != nil {
 		log.LogErrorf("%s(): %s", getCaller(), err)
-		writeErrorResponse(w, failedResponseInfo)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
 		return
 	}
 
@@ -497,10 +1055,10 @@ func (c *Console) getObjectAclHandler(w http.ResponseWriter, r *http.Request) {
 func (c *Console) setObjectAclHandler(w http.ResponseWriter, r *http.Request) {
 	failedResponseInfo := "set object acl failed!!!"
 
-	s3Client, req, err := prepareHandler(r, "bucketName", "objectName")
+	s3Client, req, err := c.prepareHandler(w, r, "bucketName", "objectName")
 	if err != nil {
 		log.LogErrorf("%s(): %s", getCaller(), err)
-		writeErrorResponse(w, failedResponseInfo)
+		writePrepareErrorResponse(w, err, failedResponseInfo)
 		return
 	}
 
@@ -521,12 +1079,34 @@ func (c *Console) setObjectAclHandler(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w)
 }
 
-func prepareHandler(r *http.Request, args ...string) (*s3.S3, map[string]interface{}, error) {
-	region := "cfs_default"
-	accessKey := "YqgyNwuMUielu8pN"
-	secretKey := "TDp9RplFfEG9VwGHvtKIV7357aPM3OvZ"
-	endPoint := "http://127.0.0.1:32793"
+// authError marks a failure to identify the caller at all (no/invalid
+// userId, auth node unreachable), as opposed to a malformed or
+// incomplete request, so handlers can answer 401 instead of the
+// generic error envelope.
+type authError struct {
+	err error
+}
+
+func (e *authError) Error() string {
+	return e.err.Error()
+}
+
+// forbiddenError marks a caller who was identified successfully but
+// was not granted an S3 capability by the auth node, so handlers can
+// answer 403 instead of lumping it in with an authentication failure.
+type forbiddenError struct {
+	err error
+}
+
+func (e *forbiddenError) Error() string {
+	return e.err.Error()
+}
 
+// prepareHandler reads and validates the JSON request body for args,
+// then authenticates the caller via Console.getS3Keys and returns an
+// *s3.S3 scoped to that caller's own access/secret key - never a
+// shared, hardcoded credential.
+func (c *Console) prepareHandler(w http.ResponseWriter, r *http.Request, args ...string) (*s3.S3, map[string]interface{}, error) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		errInfo := fmt.Sprintf("read request body failed cause by [%v]", err)
@@ -543,19 +1123,54 @@ func prepareHandler(r *http.Request, args ...string) (*s3.S3, map[string]interfa
 		}
 	}
 
-	s3Session, err := session.NewSession(&aws.Config{
-		Region:           aws.String(region),
-		Endpoint:         aws.String(endPoint),
-		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
-		DisableSSL:       aws.Bool(false),
-		S3ForcePathStyle: aws.Bool(true),
-	})
+	s3Client, err := c.getS3Client(w, r)
 	if err != nil {
-		errInfo := fmt.Sprintf("create s3 client session failed cause by [%v]", err)
-		return nil, nil, errors.New(errInfo)
+		return nil, nil, wrapAuthError(err)
+	}
+
+	return s3Client, req, nil
+}
+
+// wrapAuthError classifies a getS3Client failure as a forbiddenError when
+// the caller was identified but denied a capability, and as an authError
+// (caller could not be identified at all) otherwise.
+func wrapAuthError(err error) error {
+	if _, ok := err.(*forbiddenError); ok {
+		return err
 	}
+	return &authError{err: fmt.Errorf("authenticate request failed cause by [%v]", err)}
+}
+
+// writePrepareErrorResponse answers an error from prepareHandler or
+// getS3Client with 403 when the caller was identified but lacks an S3
+// capability, 401 when the caller could not be identified at all, and
+// falls back to the handler's own error envelope otherwise.
+func writePrepareErrorResponse(w http.ResponseWriter, err error, failedResponseInfo string) {
+	if _, ok := err.(*forbiddenError); ok {
+		writeForbiddenResponse(w, "No S3 capability granted for this user")
+		return
+	}
+	if _, ok := err.(*authError); ok {
+		writeUnauthorizedResponse(w, "Authentication failed")
+		return
+	}
+	writeErrorResponse(w, failedResponseInfo)
+}
+
+// writeUnauthorizedResponse answers a request that could not be
+// authenticated against the auth node.
+func writeUnauthorizedResponse(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"code": "Unauthorized", "message": msg})
+}
 
-	return s3.New(s3Session), req, err
+// writeForbiddenResponse answers a request from an identified caller
+// who was not granted an S3 capability by the auth node.
+func writeForbiddenResponse(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]string{"code": "Forbidden", "message": msg})
 }
 
 func getCaller() string {